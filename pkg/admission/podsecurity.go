@@ -0,0 +1,77 @@
+// Package admission holds the webhook admission plugins kiosk registers in
+// front of the Kubernetes API server to enforce per-account policy.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+	registryutil "github.com/kiosk-sh/kiosk/pkg/apiserver/registry/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PodSecurityAdmitter rejects pods whose SecurityContext violates the
+// PodSecurity policy of the Account that owns their namespace. It is
+// registered as a validating admission webhook for Pod CREATE/UPDATE.
+type PodSecurityAdmitter struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &PodSecurityAdmitter{}
+
+// Handle implements admission.Handler.
+func (a *PodSecurityAdmitter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := a.decoder.DecodeRaw(req.Object, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	account, err := registryutil.AccountForNamespace(ctx, a.Client, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if account == nil || account.Spec.PodSecurity == nil {
+		return admission.Allowed("namespace is not owned by an account with a pod security policy")
+	}
+
+	spec := resolvePodSecuritySpec(account.Spec.PodSecurity)
+	if violation := validatePod(spec, pod); violation != "" {
+		if err := a.recordViolation(ctx, account); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		return admission.Denied(fmt.Sprintf("pod violates account %q pod security policy: %s", account.Name, violation))
+	}
+
+	return admission.Allowed("")
+}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (a *PodSecurityAdmitter) InjectDecoder(d *admission.Decoder) error {
+	a.decoder = d
+	return nil
+}
+
+// recordViolation increments the violating account's PodSecurityViolations
+// counter, so operators can observe rejections without scraping webhook logs.
+// Rejections for different namespaces or pods owned by the same account can
+// land here concurrently, so it retries on conflict rather than risking a
+// lost update from a stale copy of account.
+func (a *PodSecurityAdmitter) recordViolation(ctx context.Context, account *configv1alpha1.Account) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &configv1alpha1.Account{}
+		if err := a.Client.Get(ctx, client.ObjectKeyFromObject(account), latest); err != nil {
+			return err
+		}
+
+		latest.Status.PodSecurityViolations++
+		return a.Client.Status().Update(ctx, latest)
+	})
+}
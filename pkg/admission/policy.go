@@ -0,0 +1,263 @@
+package admission
+
+import (
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// profileRestricted is the only named PodSecuritySpec.Profile kiosk
+// recognizes today.
+const profileRestricted = "restricted"
+
+var restrictedPodSecuritySpec = configv1alpha1.PodSecuritySpec{
+	AllowPrivilegeEscalation: boolPtr(false),
+	RunAsUser:                configv1alpha1.RunAsUserRule{Rule: "MustRunAsNonRoot"},
+	SELinux:                  configv1alpha1.SELinuxRule{Rule: "RunAsAny"},
+	SupplementalGroups:       configv1alpha1.SupplementalGroupsRule{Rule: "RunAsAny"},
+	AllowedVolumes: []string{
+		"configMap", "downwardAPI", "emptyDir", "persistentVolumeClaim",
+		"projected", "secret",
+	},
+	ForbidHostNetwork: true,
+	ForbidHostPID:     true,
+	ForbidHostIPC:     true,
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// resolvePodSecuritySpec merges spec with its named profile, if any. Fields
+// set explicitly on spec override the profile's default for that field. An
+// empty list counts as unset, and a false ForbidHost* field never overrides
+// a profile's true.
+func resolvePodSecuritySpec(spec *configv1alpha1.PodSecuritySpec) configv1alpha1.PodSecuritySpec {
+	resolved := configv1alpha1.PodSecuritySpec{}
+	if spec.Profile == profileRestricted {
+		resolved = *restrictedPodSecuritySpec.DeepCopy()
+	}
+
+	if spec.AllowPrivilegeEscalation != nil {
+		resolved.AllowPrivilegeEscalation = spec.AllowPrivilegeEscalation
+	}
+	if spec.RunAsUser.Rule != "" {
+		resolved.RunAsUser = spec.RunAsUser
+	}
+	if spec.SELinux.Rule != "" {
+		resolved.SELinux = spec.SELinux
+	}
+	if spec.SupplementalGroups.Rule != "" {
+		resolved.SupplementalGroups = spec.SupplementalGroups
+	}
+	if len(spec.AllowedCapabilities) > 0 {
+		resolved.AllowedCapabilities = spec.AllowedCapabilities
+	}
+	if len(spec.AllowedHostPaths) > 0 {
+		resolved.AllowedHostPaths = spec.AllowedHostPaths
+	}
+	if len(spec.AllowedVolumes) > 0 {
+		resolved.AllowedVolumes = spec.AllowedVolumes
+	}
+	if spec.ForbidHostNetwork {
+		resolved.ForbidHostNetwork = true
+	}
+	if spec.ForbidHostPID {
+		resolved.ForbidHostPID = true
+	}
+	if spec.ForbidHostIPC {
+		resolved.ForbidHostIPC = true
+	}
+
+	return resolved
+}
+
+// validatePod returns a human readable description of the first policy
+// violation it finds in pod, or "" if pod complies with spec.
+func validatePod(spec configv1alpha1.PodSecuritySpec, pod *corev1.Pod) string {
+	if spec.ForbidHostNetwork && pod.Spec.HostNetwork {
+		return "hostNetwork is not allowed"
+	}
+	if spec.ForbidHostPID && pod.Spec.HostPID {
+		return "hostPID is not allowed"
+	}
+	if spec.ForbidHostIPC && pod.Spec.HostIPC {
+		return "hostIPC is not allowed"
+	}
+
+	if violation := validateVolumes(spec, pod); violation != "" {
+		return violation
+	}
+
+	if violation := validatePodSecurityContext(spec, pod.Spec.SecurityContext); violation != "" {
+		return violation
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		if violation := validateContainerSecurityContext(spec, container); violation != "" {
+			return violation
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if violation := validateContainerSecurityContext(spec, container); violation != "" {
+			return violation
+		}
+	}
+
+	return ""
+}
+
+func validateVolumes(spec configv1alpha1.PodSecuritySpec, pod *corev1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		volumeType := volumeSourceType(volume)
+
+		if len(spec.AllowedVolumes) > 0 && !containsString(spec.AllowedVolumes, volumeType) {
+			return "volume type " + volumeType + " is not allowed"
+		}
+
+		if volumeType == "hostPath" && volume.HostPath != nil {
+			allowedPath, ok := allowedHostPath(spec.AllowedHostPaths, volume.HostPath.Path)
+			if !ok {
+				return "hostPath " + volume.HostPath.Path + " is not allowed"
+			}
+			if allowedPath.ReadOnly && !volumeMountedReadOnly(pod, volume.Name) {
+				return "hostPath " + volume.HostPath.Path + " must be mounted read-only"
+			}
+		}
+	}
+
+	return ""
+}
+
+func allowedHostPath(allowed []configv1alpha1.AllowedHostPath, path string) (configv1alpha1.AllowedHostPath, bool) {
+	for _, hostPath := range allowed {
+		if hasPrefix(path, hostPath.PathPrefix) {
+			return hostPath, true
+		}
+	}
+
+	return configv1alpha1.AllowedHostPath{}, false
+}
+
+// volumeMountedReadOnly reports whether every container - init or not - that
+// mounts volumeName does so with readOnly: true.
+func volumeMountedReadOnly(pod *corev1.Pod, volumeName string) bool {
+	for _, container := range pod.Spec.InitContainers {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volumeName && !mount.ReadOnly {
+				return false
+			}
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volumeName && !mount.ReadOnly {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func validatePodSecurityContext(spec configv1alpha1.PodSecuritySpec, sc *corev1.PodSecurityContext) string {
+	if sc == nil {
+		return ""
+	}
+
+	if spec.RunAsUser.Rule == "MustRunAsNonRoot" {
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			return "runAsUser 0 is not allowed"
+		}
+	}
+
+	if spec.SELinux.Rule == "MustRunAs" && sc.SELinuxOptions == nil {
+		return "seLinuxOptions must be set"
+	}
+
+	if spec.SupplementalGroups.Rule == "MustRunAs" && len(sc.SupplementalGroups) == 0 {
+		return "supplementalGroups must be set"
+	}
+
+	return ""
+}
+
+func validateContainerSecurityContext(spec configv1alpha1.PodSecuritySpec, container corev1.Container) string {
+	sc := container.SecurityContext
+	if sc == nil {
+		return ""
+	}
+
+	if sc.Privileged != nil && *sc.Privileged {
+		return "container " + container.Name + " must not run privileged"
+	}
+
+	if spec.AllowPrivilegeEscalation != nil && !*spec.AllowPrivilegeEscalation {
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			return "container " + container.Name + " must set allowPrivilegeEscalation: false"
+		}
+	}
+
+	if spec.RunAsUser.Rule == "MustRunAsNonRoot" {
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			return "container " + container.Name + " must not run as root"
+		}
+	}
+
+	if spec.SELinux.Rule == "MustRunAs" && sc.SELinuxOptions == nil {
+		return "container " + container.Name + " must set seLinuxOptions"
+	}
+
+	if sc.Capabilities != nil {
+		for _, capability := range sc.Capabilities.Add {
+			if !containsCapability(spec.AllowedCapabilities, capability) {
+				return "container " + container.Name + " adds disallowed capability " + string(capability)
+			}
+		}
+	}
+
+	return ""
+}
+
+func volumeSourceType(volume corev1.Volume) string {
+	switch {
+	case volume.HostPath != nil:
+		return "hostPath"
+	case volume.EmptyDir != nil:
+		return "emptyDir"
+	case volume.ConfigMap != nil:
+		return "configMap"
+	case volume.Secret != nil:
+		return "secret"
+	case volume.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim"
+	case volume.Projected != nil:
+		return "projected"
+	case volume.DownwardAPI != nil:
+		return "downwardAPI"
+	default:
+		return "other"
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsCapability(values []corev1.Capability, value corev1.Capability) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
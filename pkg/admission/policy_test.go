@@ -0,0 +1,203 @@
+package admission
+
+import (
+	"reflect"
+	"testing"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolvePodSecuritySpec(t *testing.T) {
+	tests := map[string]struct {
+		spec     *configv1alpha1.PodSecuritySpec
+		expected configv1alpha1.PodSecuritySpec
+	}{
+		"no profile, no overrides": {
+			spec:     &configv1alpha1.PodSecuritySpec{},
+			expected: configv1alpha1.PodSecuritySpec{},
+		},
+		"restricted profile": {
+			spec:     &configv1alpha1.PodSecuritySpec{Profile: profileRestricted},
+			expected: restrictedPodSecuritySpec,
+		},
+		"restricted profile with an override": {
+			spec: &configv1alpha1.PodSecuritySpec{
+				Profile:   profileRestricted,
+				RunAsUser: configv1alpha1.RunAsUserRule{Rule: "RunAsAny"},
+			},
+			expected: func() configv1alpha1.PodSecuritySpec {
+				expected := *restrictedPodSecuritySpec.DeepCopy()
+				expected.RunAsUser = configv1alpha1.RunAsUserRule{Rule: "RunAsAny"}
+				return expected
+			}(),
+		},
+	}
+
+	for name, test := range tests {
+		resolved := resolvePodSecuritySpec(test.spec)
+		if !reflect.DeepEqual(resolved, test.expected) {
+			t.Fatalf("%s: expected %#+v, got %#+v", name, test.expected, resolved)
+		}
+	}
+}
+
+func TestValidatePod(t *testing.T) {
+	restricted := resolvePodSecuritySpec(&configv1alpha1.PodSecuritySpec{Profile: profileRestricted})
+
+	runAsUser := func(uid int64) *int64 { return &uid }
+	boolVal := func(b bool) *bool { return &b }
+
+	tests := map[string]struct {
+		spec      configv1alpha1.PodSecuritySpec
+		pod       *corev1.Pod
+		violation bool
+	}{
+		"compliant pod": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: runAsUser(1000)},
+				Containers: []corev1.Container{{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						RunAsUser:                runAsUser(1000),
+						AllowPrivilegeEscalation: boolVal(false),
+					},
+				}},
+			}},
+			violation: false,
+		},
+		"hostNetwork forbidden": {
+			spec:      restricted,
+			pod:       &corev1.Pod{Spec: corev1.PodSpec{HostNetwork: true}},
+			violation: true,
+		},
+		"disallowed volume type": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}}}},
+			}},
+			violation: true,
+		},
+		"hostPath outside allowed prefix": {
+			spec: configv1alpha1.PodSecuritySpec{
+				AllowedVolumes:   []string{"hostPath"},
+				AllowedHostPaths: []configv1alpha1.AllowedHostPath{{PathPrefix: "/var/run"}},
+			},
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/etc"}}}},
+			}},
+			violation: true,
+		},
+		"hostPath must be mounted read-only": {
+			spec: configv1alpha1.PodSecuritySpec{
+				AllowedVolumes:   []string{"hostPath"},
+				AllowedHostPaths: []configv1alpha1.AllowedHostPath{{PathPrefix: "/var/run", ReadOnly: true}},
+			},
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}}},
+				Containers: []corev1.Container{{
+					Name:         "app",
+					VolumeMounts: []corev1.VolumeMount{{Name: "data", ReadOnly: false}},
+				}},
+			}},
+			violation: true,
+		},
+		"root runAsUser rejected": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: runAsUser(0)},
+			}},
+			violation: true,
+		},
+		"privileged container rejected": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:            "app",
+					SecurityContext: &corev1.SecurityContext{Privileged: boolVal(true)},
+				}},
+			}},
+			violation: true,
+		},
+		"disallowed capability rejected": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: boolVal(false),
+						Capabilities:             &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+					},
+				}},
+			}},
+			violation: true,
+		},
+		"seLinuxOptions required by MustRunAs": {
+			spec: configv1alpha1.PodSecuritySpec{SELinux: configv1alpha1.SELinuxRule{Rule: "MustRunAs"}},
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{},
+			}},
+			violation: true,
+		},
+		"supplementalGroups required by MustRunAs": {
+			spec: configv1alpha1.PodSecuritySpec{SupplementalGroups: configv1alpha1.SupplementalGroupsRule{Rule: "MustRunAs"}},
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{},
+			}},
+			violation: true,
+		},
+		"privileged init container rejected": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{
+					Name:            "init",
+					SecurityContext: &corev1.SecurityContext{Privileged: boolVal(true)},
+				}},
+			}},
+			violation: true,
+		},
+		"root runAsUser on init container rejected": {
+			spec: restricted,
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{
+					Name: "init",
+					SecurityContext: &corev1.SecurityContext{
+						RunAsUser:                runAsUser(0),
+						AllowPrivilegeEscalation: boolVal(false),
+					},
+				}},
+			}},
+			violation: true,
+		},
+		"hostPath must be mounted read-only by init container too": {
+			spec: configv1alpha1.PodSecuritySpec{
+				AllowedVolumes:   []string{"hostPath"},
+				AllowedHostPaths: []configv1alpha1.AllowedHostPath{{PathPrefix: "/var/run", ReadOnly: true}},
+			},
+			pod: &corev1.Pod{Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}}},
+				InitContainers: []corev1.Container{{
+					Name:         "init",
+					VolumeMounts: []corev1.VolumeMount{{Name: "data", ReadOnly: false}},
+				}},
+				Containers: []corev1.Container{{
+					Name:         "app",
+					VolumeMounts: []corev1.VolumeMount{{Name: "data", ReadOnly: true}},
+				}},
+			}},
+			violation: true,
+		},
+	}
+
+	for name, test := range tests {
+		violation := validatePod(test.spec, test.pod)
+		if test.violation && violation == "" {
+			t.Fatalf("%s: expected a violation, got none", name)
+		}
+		if !test.violation && violation != "" {
+			t.Fatalf("%s: expected no violation, got %q", name, violation)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccountSpec defines the desired state of an Account
+type AccountSpec struct {
+	// Subjects holds references to the objects the account applies to
+	// +optional
+	Subjects []rbacv1.Subject `json:"subjects,omitempty"`
+
+	// PodSecurity restricts the SecurityContext pods may request in any
+	// namespace owned by this account. Fields left unset fall back to the
+	// named Profile's defaults, or are unrestricted if no Profile is set.
+	// +optional
+	PodSecurity *PodSecuritySpec `json:"podSecurity,omitempty"`
+}
+
+// AccountStatus defines the observed state of an Account
+type AccountStatus struct {
+	// Namespaces are the namespaces that are currently owned by this account
+	// +optional
+	Namespaces []AccountStatusNamespace `json:"namespaces,omitempty"`
+
+	// PodSecurityViolations counts the pods that have been rejected by the
+	// pod security admission plugin for violating this account's
+	// PodSecurity policy.
+	// +optional
+	PodSecurityViolations int32 `json:"podSecurityViolations,omitempty"`
+}
+
+// PodSecuritySpec mirrors the fields of a classic Kubernetes
+// PodSecurityPolicy that matter for pods running in an account's
+// namespaces. Setting Profile to "restricted" fills in every field below
+// with the same defaults as the upstream restricted PSP, without having to
+// enumerate them.
+type PodSecuritySpec struct {
+	// Profile selects a built-in baseline for the fields below. Currently
+	// only "restricted" is recognized. Fields set explicitly alongside a
+	// Profile override that profile's default for just that field. An empty
+	// list counts as unset, and ForbidHostNetwork/PID/IPC can only be
+	// switched on this way: setting one to false keeps the profile's value.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
+	// AllowPrivilegeEscalation mirrors PodSecurityPolicySpec.AllowPrivilegeEscalation.
+	// +optional
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation,omitempty"`
+
+	// RunAsUser mirrors PodSecurityPolicySpec.RunAsUser.
+	// +optional
+	RunAsUser RunAsUserRule `json:"runAsUser,omitempty"`
+
+	// SELinux mirrors PodSecurityPolicySpec.SELinux.
+	// +optional
+	SELinux SELinuxRule `json:"seLinux,omitempty"`
+
+	// SupplementalGroups mirrors PodSecurityPolicySpec.SupplementalGroups.
+	// +optional
+	SupplementalGroups SupplementalGroupsRule `json:"supplementalGroups,omitempty"`
+
+	// AllowedCapabilities mirrors PodSecurityPolicySpec.AllowedCapabilities.
+	// +optional
+	AllowedCapabilities []corev1.Capability `json:"allowedCapabilities,omitempty"`
+
+	// AllowedHostPaths mirrors PodSecurityPolicySpec.AllowedHostPaths.
+	// +optional
+	AllowedHostPaths []AllowedHostPath `json:"allowedHostPaths,omitempty"`
+
+	// AllowedVolumes restricts the volume source types a pod may use (e.g.
+	// "configMap", "emptyDir", "secret"). An empty list means every volume
+	// type is allowed.
+	// +optional
+	AllowedVolumes []string `json:"allowedVolumes,omitempty"`
+
+	// ForbidHostNetwork disallows pods that set hostNetwork: true.
+	// +optional
+	ForbidHostNetwork bool `json:"forbidHostNetwork,omitempty"`
+
+	// ForbidHostPID disallows pods that set hostPID: true.
+	// +optional
+	ForbidHostPID bool `json:"forbidHostPID,omitempty"`
+
+	// ForbidHostIPC disallows pods that set hostIPC: true.
+	// +optional
+	ForbidHostIPC bool `json:"forbidHostIPC,omitempty"`
+}
+
+// RunAsUserRule mirrors PodSecurityPolicy's RunAsUserStrategyOptions, minus
+// the ID range options kiosk does not expose yet.
+type RunAsUserRule struct {
+	// Rule is one of "MustRunAsNonRoot" or "RunAsAny".
+	Rule string `json:"rule,omitempty"`
+}
+
+// SELinuxRule mirrors PodSecurityPolicy's SELinuxStrategyOptions, minus the
+// concrete level kiosk does not expose yet.
+type SELinuxRule struct {
+	// Rule is one of "MustRunAs" or "RunAsAny".
+	Rule string `json:"rule,omitempty"`
+}
+
+// SupplementalGroupsRule mirrors PodSecurityPolicy's
+// SupplementalGroupsStrategyOptions, minus the concrete ID ranges kiosk does
+// not expose yet.
+type SupplementalGroupsRule struct {
+	// Rule is one of "MustRunAs" or "RunAsAny".
+	Rule string `json:"rule,omitempty"`
+}
+
+// AllowedHostPath mirrors PodSecurityPolicy's AllowedHostPath.
+type AllowedHostPath struct {
+	// PathPrefix is the path prefix a hostPath volume must match.
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// ReadOnly requires the volume to be mounted read-only.
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// AccountStatusNamespace describes a namespace that is owned by an account
+type AccountStatusNamespace struct {
+	// Name is the name of the namespace
+	Name string `json:"name,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// Account is the Schema for the accounts API
+type Account struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccountSpec   `json:"spec,omitempty"`
+	Status AccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AccountList contains a list of Account
+type AccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Account `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Account{}, &AccountList{})
+}
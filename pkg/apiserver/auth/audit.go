@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// NamespaceGrant records why a subject was granted access to a namespace:
+// the RoleBinding's RoleRef that resolved the grant, and the PolicyRule
+// inside that (Cluster)Role that matched the verb being checked.
+type NamespaceGrant struct {
+	Namespace string
+	RoleRef   string
+	RuleIndex string
+	Rule      rbacv1.PolicyRule
+}
+
+// AccountGrant records that a subject was granted access to an Account.
+// Accounts are granted directly through Account.Spec.Subjects rather than
+// through a RoleBinding, so there is no RoleRef or PolicyRule to record -
+// the Account name is the only provenance there is.
+type AccountGrant struct {
+	Account string
+}
+
+// AuditEvent is a single structured RBAC grant decision, with enough
+// provenance to answer "why was this subject allowed to do this" without
+// re-deriving it from the raw RoleBindings. Namespace/RoleRef/RuleIndex/Rule
+// are populated for namespace grants; Account is populated for account
+// grants. A single event never has both set.
+type AuditEvent struct {
+	SubjectKind string
+	SubjectName string
+	Verb        string
+	Resource    string
+	Namespace   string
+	RoleRef     string
+	RuleIndex   string
+	Rule        rbacv1.PolicyRule
+	Account     string
+}
+
+// AuditSink receives an AuditEvent for every RBAC grant authCache resolves.
+// The default sink logs each event through the logr.Logger passed to
+// NewAuthCache; callers can install their own via SetAuditSink to forward
+// events to an external audit backend instead.
+type AuditSink interface {
+	Audit(event AuditEvent)
+}
+
+// logAuditSink is the AuditSink installed by NewAuthCache before a caller
+// has a chance to install their own. It just logs.
+type logAuditSink struct {
+	log logr.Logger
+}
+
+// Audit implements AuditSink.
+func (s *logAuditSink) Audit(event AuditEvent) {
+	s.log.Info("rbac grant",
+		"subjectKind", event.SubjectKind,
+		"subjectName", event.SubjectName,
+		"verb", event.Verb,
+		"resource", event.Resource,
+		"namespace", event.Namespace,
+		"roleRef", event.RoleRef,
+		"ruleIndex", event.RuleIndex,
+		"ruleResources", event.Rule.Resources,
+		"ruleVerbs", event.Rule.Verbs,
+		"ruleResourceNames", event.Rule.ResourceNames,
+		"ruleNonResourceURLs", event.Rule.NonResourceURLs,
+		"account", event.Account,
+	)
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// RetrieveAllowedNamespacesWithReason lets fakeAccessor (defined in
+// cache_test.go) satisfy the accessor interface's audit extension. Every
+// namespace it already knows about is reported as granted by a single fake
+// role, since fakeAccessor has no notion of RoleBindings or PolicyRules.
+// Like RetrieveAllowedNamespaces, it answers from allowedNamespacesByVerb
+// when the subject has an entry there.
+func (f *fakeAccessor) RetrieveAllowedNamespacesWithReason(ctx context.Context, subject, verb string) ([]NamespaceGrant, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	namespaces := f.allowedNamespaces[subject]
+	if byVerb, ok := f.allowedNamespacesByVerb[subject]; ok {
+		namespaces = byVerb[verb]
+	}
+
+	grants := []NamespaceGrant{}
+	for _, namespace := range namespaces {
+		grants = append(grants, NamespaceGrant{
+			Namespace: namespace,
+			RoleRef:   "fake-admin-role",
+			RuleIndex: "0",
+			Rule:      rbacv1.PolicyRule{Verbs: []string{verb}, Resources: []string{"namespaces"}},
+		})
+	}
+
+	return grants, nil
+}
+
+// RetrieveAllowedAccountsWithReason lets fakeAccessor satisfy the accessor
+// interface's audit extension for accounts, the same way
+// RetrieveAllowedNamespacesWithReason does for namespaces.
+func (f *fakeAccessor) RetrieveAllowedAccountsWithReason(ctx context.Context, subject, verb string) ([]AccountGrant, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	grants := []AccountGrant{}
+	for _, account := range f.allowedAccounts[subject] {
+		grants = append(grants, AccountGrant{Account: account})
+	}
+
+	return grants, nil
+}
+
+type fakeAuditSink struct {
+	lock   sync.Mutex
+	events []AuditEvent
+}
+
+func (s *fakeAuditSink) Audit(event AuditEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.events = append(s.events, event)
+}
+
+func TestAuditLog(t *testing.T) {
+	accessor := &fakeAccessor{
+		allowedNamespaces: map[string][]string{
+			"user:foo": {"test"},
+		},
+	}
+
+	cache := &authCache{
+		log:                        zap.New(func(o *zap.Options) {}),
+		accessor:                   accessor,
+		allowedNamespaceStore:      newCacheStore(),
+		allowedNamespaceGrantStore: newNamespaceGrantStore(),
+	}
+
+	sink := &fakeAuditSink{}
+	cache.SetAuditSink(sink)
+
+	namespaces, err := cache.GetNamespacesForUser(&user.DefaultInfo{Name: "foo"}, "get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(namespaces) != 1 || namespaces[0] != "test" {
+		t.Fatalf("expected namespaces [test], got %#+v", namespaces)
+	}
+
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(sink.events))
+	}
+	if sink.events[0].RoleRef != "fake-admin-role" {
+		t.Fatalf("expected audit event to reference fake-admin-role, got %#+v", sink.events[0])
+	}
+}
+
+// countingAccessor wraps fakeAccessor and counts calls to
+// RetrieveAllowedNamespacesWithReason, so a test can assert that a warm
+// cache never falls through to a synchronous RBAC re-evaluation.
+type countingAccessor struct {
+	fakeAccessor
+	namespaceReasonCalls int32
+}
+
+func (f *countingAccessor) RetrieveAllowedNamespacesWithReason(ctx context.Context, subject, verb string) ([]NamespaceGrant, error) {
+	atomic.AddInt32(&f.namespaceReasonCalls, 1)
+	return f.fakeAccessor.RetrieveAllowedNamespacesWithReason(ctx, subject, verb)
+}
+
+// TestAuditLogUsesWarmCache guards against auditNamespaceGrants
+// re-evaluating RBAC synchronously once allowedNamespaceGrantStore already
+// holds the subject/verb's provenance - that re-evaluation is exactly the
+// O(subjects x RBAC-eval)-per-request cost the cache exists to avoid.
+func TestAuditLogUsesWarmCache(t *testing.T) {
+	accessor := &countingAccessor{
+		fakeAccessor: fakeAccessor{allowedNamespaces: map[string][]string{"user:foo": {"test"}}},
+	}
+
+	cache := &authCache{
+		log:                        zap.New(func(o *zap.Options) {}),
+		accessor:                   accessor,
+		allowedNamespaceStore:      newCacheStore(),
+		allowedNamespaceGrantStore: newNamespaceGrantStore(),
+	}
+	cache.allowedNamespaceStore.Set(cacheKey("user:foo", "get"), []string{"test"})
+	cache.allowedNamespaceGrantStore.Set(cacheKey("user:foo", "get"), []NamespaceGrant{
+		{Namespace: "test", RoleRef: "fake-admin-role"},
+	})
+
+	sink := &fakeAuditSink{}
+	cache.SetAuditSink(sink)
+
+	namespaces, err := cache.GetNamespacesForUser(&user.DefaultInfo{Name: "foo"}, "get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(namespaces) != 1 || namespaces[0] != "test" {
+		t.Fatalf("expected namespaces [test], got %#+v", namespaces)
+	}
+
+	if calls := atomic.LoadInt32(&accessor.namespaceReasonCalls); calls != 0 {
+		t.Fatalf("expected a warm cache to never re-evaluate RBAC for audit provenance, got %d calls", calls)
+	}
+
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(sink.events))
+	}
+	if sink.events[0].RoleRef != "fake-admin-role" {
+		t.Fatalf("expected audit event to reference fake-admin-role, got %#+v", sink.events[0])
+	}
+}
@@ -0,0 +1,948 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Informer is the subset of a client-go shared informer that authCache
+// depends on to learn about RoleBinding and Account changes.
+type Informer interface {
+	AddEventHandler(handler toolscache.ResourceEventHandler)
+	HasSynced() bool
+}
+
+// InformerCache is the subset of controller-runtime's cache.Cache that
+// authCache needs in order to obtain the informers for the types it watches.
+type InformerCache interface {
+	GetInformer(ctx context.Context, obj client.Object) (Informer, error)
+}
+
+// accessor resolves the namespaces and accounts a subject is allowed to
+// access for a given verb. The default implementation evaluates this against
+// RoleBindings and Accounts found in the cluster.
+type accessor interface {
+	RetrieveAllowedNamespaces(ctx context.Context, subject, verb string) ([]string, error)
+	RetrieveAllowedAccounts(ctx context.Context, subject, verb string) ([]string, error)
+
+	// RetrieveAllowedNamespacesWithReason is RetrieveAllowedNamespaces plus
+	// the provenance of each grant, for audit logging.
+	RetrieveAllowedNamespacesWithReason(ctx context.Context, subject, verb string) ([]NamespaceGrant, error)
+
+	// RetrieveAllowedAccountsWithReason is RetrieveAllowedAccounts plus the
+	// provenance of each grant, for audit logging.
+	RetrieveAllowedAccountsWithReason(ctx context.Context, subject, verb string) ([]AccountGrant, error)
+}
+
+// AuthCache resolves which namespaces and accounts a user is allowed to
+// access. It is kept up to date by watching RoleBindings and Accounts
+// instead of evaluating access on every request.
+type AuthCache interface {
+	// Run starts the informer event handlers and queue workers. It blocks
+	// until stopCh is closed.
+	Run(stopCh <-chan struct{})
+
+	// WaitForCacheSync blocks until the RoleBinding, ClusterRoleBinding and
+	// Account informers have completed their initial list AND every event that listing
+	// produced has been reconciled into the cache. Callers (admission
+	// webhooks, list handlers) must call this once after starting Run and
+	// before trusting GetNamespacesForUser/GetAccountsForUser - otherwise a
+	// lookup made while the cache is still warming up can spuriously report
+	// that a subject has no access.
+	WaitForCacheSync(stopCh <-chan struct{}) bool
+
+	GetNamespacesForUser(user user.Info, verb string) ([]string, error)
+	GetAccountsForUser(user user.Info, verb string) ([]string, error)
+
+	// GetSubjectsForNamespace returns the subject keys (as produced by
+	// subjectKey) that have been granted verb on namespace, either directly
+	// via a RoleBinding in that namespace or cluster-wide via a
+	// ClusterRoleBinding. Unlike GetNamespacesForUser it has no accessor
+	// fallback, since it answers a question the accessor was never asked
+	// before: which subjects can reach a namespace, rather than which
+	// namespaces a subject can reach. It is an index lookup plus one
+	// Role/ClusterRole Get per matching binding, to check that binding's
+	// RoleRef grants verb.
+	GetSubjectsForNamespace(namespace, verb string) ([]string, error)
+
+	// SetAuditSink replaces the AuditSink that receives an event for every
+	// RBAC grant decision. Passing nil disables auditing.
+	SetAuditSink(sink AuditSink)
+}
+
+var supportedVerbs = map[string]bool{
+	"get":    true,
+	"list":   true,
+	"watch":  true,
+	"create": true,
+	"update": true,
+	"delete": true,
+}
+
+type queueItemKind string
+
+const (
+	queueItemNamespace queueItemKind = "namespace"
+	queueItemAccount   queueItemKind = "account"
+)
+
+type queueItem struct {
+	kind queueItemKind
+	key  string
+}
+
+type authCache struct {
+	client client.Client
+	log    logr.Logger
+
+	accessor accessor
+
+	// auditSink receives an AuditEvent for every RBAC grant decision made by
+	// GetNamespacesForUser. Never nil outside of tests that clear it.
+	auditSink AuditSink
+
+	queue workqueue.RateLimitingInterface
+
+	roleBindingInformer        Informer
+	clusterRoleBindingInformer Informer
+	accountInformer            Informer
+
+	allowedNamespaceStore *cacheStore
+	allowedAccountStore   *cacheStore
+
+	// allowedNamespaceGrantStore/allowedAccountGrantStore cache the same
+	// (subject, verb) grants as allowedNamespaceStore/allowedAccountStore,
+	// but with provenance attached, so auditNamespaceGrants/auditAccountGrants
+	// can report why a grant was made without re-evaluating RBAC synchronously
+	// on every call - sync() is the only place that does that evaluation.
+	allowedNamespaceGrantStore *namespaceGrantStore
+	allowedAccountGrantStore   *accountGrantStore
+
+	// subjectIndexer maps a namespace to the RoleBindings granting access to
+	// it, kept up to date incrementally from informer deltas instead of
+	// being recomputed by asking the accessor about every known subject.
+	// Each record only carries the RoleRef it was bound through, not a
+	// resolved PolicyRule, so GetSubjectsForNamespace still resolves that
+	// RoleRef against the requested verb via matchingRule at lookup time.
+	subjectIndexer toolscache.Indexer
+
+	// clusterRoleBindingStore holds the subjects granted access cluster-wide
+	// by a ClusterRoleBinding, which apply to every namespace and therefore
+	// aren't indexed by namespace the way RoleBindings are.
+	clusterRoleBindingStore toolscache.Store
+
+	// inFlight counts queue items that have been dequeued but not yet
+	// reconciled, so WaitForCacheSync can tell an empty queue apart from a
+	// queue whose last item is still being processed.
+	inFlight int32
+}
+
+// subjectIndexByNamespace is the name of the subjectIndexer index that maps
+// a namespace to the RoleBindings granting access to it.
+const subjectIndexByNamespace = "namespace"
+
+// subjectRecord is a RoleBinding or ClusterRoleBinding projected down to
+// just what the namespace/subject indexer needs: the informer store key, the
+// namespace it applies to (empty for a ClusterRoleBinding), the subjects it
+// grants access to, and the RoleRef those subjects were granted through -
+// GetSubjectsForNamespace resolves RoleRef against the verb it was asked
+// about before including these subjects in its result.
+type subjectRecord struct {
+	key       string
+	namespace string
+	subjects  []string
+	roleRef   rbacv1.RoleRef
+}
+
+func subjectRecordKeyFunc(obj interface{}) (string, error) {
+	return obj.(*subjectRecord).key, nil
+}
+
+func subjectRecordNamespaceIndexFunc(obj interface{}) ([]string, error) {
+	record := obj.(*subjectRecord)
+	if record.namespace == "" {
+		return nil, nil
+	}
+
+	return []string{record.namespace}, nil
+}
+
+func roleBindingSubjectRecord(roleBinding *rbacv1.RoleBinding) *subjectRecord {
+	return &subjectRecord{
+		key:       roleBinding.Namespace + "/" + roleBinding.Name,
+		namespace: roleBinding.Namespace,
+		subjects:  subjectKeys(roleBinding.Subjects),
+		roleRef:   roleBinding.RoleRef,
+	}
+}
+
+func clusterRoleBindingSubjectRecord(clusterRoleBinding *rbacv1.ClusterRoleBinding) *subjectRecord {
+	return &subjectRecord{
+		key:      clusterRoleBinding.Name,
+		subjects: subjectKeys(clusterRoleBinding.Subjects),
+		roleRef:  clusterRoleBinding.RoleRef,
+	}
+}
+
+func subjectKeys(subjects []rbacv1.Subject) []string {
+	keys := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		keys = append(keys, subjectKey(subject))
+	}
+
+	return keys
+}
+
+// NewAuthCache creates an AuthCache backed by the given client and informer
+// cache. Call Run to start processing events, and WaitForCacheSync before
+// trusting lookups.
+func NewAuthCache(c client.Client, informerCache InformerCache, log logr.Logger) (AuthCache, error) {
+	roleBindingInformer, err := informerCache.GetInformer(context.Background(), &rbacv1.RoleBinding{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting role binding informer: %w", err)
+	}
+
+	clusterRoleBindingInformer, err := informerCache.GetInformer(context.Background(), &rbacv1.ClusterRoleBinding{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster role binding informer: %w", err)
+	}
+
+	accountInformer, err := informerCache.GetInformer(context.Background(), &configv1alpha1.Account{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting account informer: %w", err)
+	}
+
+	return &authCache{
+		client:    c,
+		log:       log,
+		accessor:  newDefaultAccessor(c),
+		auditSink: &logAuditSink{log: log},
+
+		queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+
+		roleBindingInformer:        roleBindingInformer,
+		clusterRoleBindingInformer: clusterRoleBindingInformer,
+		accountInformer:            accountInformer,
+
+		allowedNamespaceStore: newCacheStore(),
+		allowedAccountStore:   newCacheStore(),
+
+		allowedNamespaceGrantStore: newNamespaceGrantStore(),
+		allowedAccountGrantStore:   newAccountGrantStore(),
+
+		subjectIndexer: toolscache.NewIndexer(subjectRecordKeyFunc, toolscache.Indexers{
+			subjectIndexByNamespace: subjectRecordNamespaceIndexFunc,
+		}),
+		clusterRoleBindingStore: toolscache.NewStore(subjectRecordKeyFunc),
+	}, nil
+}
+
+func (c *authCache) Run(stopCh <-chan struct{}) {
+	defer c.queue.ShutDown()
+
+	c.roleBindingInformer.AddEventHandler(c.roleBindingEventHandler())
+	c.clusterRoleBindingInformer.AddEventHandler(c.clusterRoleBindingEventHandler())
+	c.accountInformer.AddEventHandler(c.accountEventHandler())
+
+	go wait.Until(c.runWorker, time.Second, stopCh)
+
+	<-stopCh
+}
+
+// WaitForCacheSync blocks until all three informers report HasSynced and the
+// queue has drained the backlog that their initial list produced. See the
+// AuthCache interface doc for why this barrier exists.
+func (c *authCache) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	if !toolscache.WaitForCacheSync(stopCh, c.roleBindingInformer.HasSynced, c.clusterRoleBindingInformer.HasSynced, c.accountInformer.HasSynced) {
+		return false
+	}
+
+	return toolscache.WaitForCacheSync(stopCh, c.queueDrained)
+}
+
+func (c *authCache) queueDrained() bool {
+	return c.queue.Len() == 0 && atomic.LoadInt32(&c.inFlight) == 0
+}
+
+func (c *authCache) roleBindingEventHandler() toolscache.ResourceEventHandler {
+	update := func(obj interface{}) {
+		roleBinding, ok := obj.(*rbacv1.RoleBinding)
+		if !ok {
+			return
+		}
+
+		for _, subject := range roleBinding.Subjects {
+			c.queue.Add(queueItem{kind: queueItemNamespace, key: subjectKey(subject)})
+		}
+
+		_ = c.subjectIndexer.Update(roleBindingSubjectRecord(roleBinding))
+	}
+
+	remove := func(obj interface{}) {
+		roleBinding, ok := obj.(*rbacv1.RoleBinding)
+		if !ok {
+			return
+		}
+
+		for _, subject := range roleBinding.Subjects {
+			c.queue.Add(queueItem{kind: queueItemNamespace, key: subjectKey(subject)})
+		}
+
+		_ = c.subjectIndexer.Delete(roleBindingSubjectRecord(roleBinding))
+	}
+
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(oldObj, newObj interface{}) { update(newObj) },
+		DeleteFunc: remove,
+	}
+}
+
+func (c *authCache) clusterRoleBindingEventHandler() toolscache.ResourceEventHandler {
+	update := func(obj interface{}) {
+		clusterRoleBinding, ok := obj.(*rbacv1.ClusterRoleBinding)
+		if !ok {
+			return
+		}
+
+		for _, subject := range clusterRoleBinding.Subjects {
+			c.queue.Add(queueItem{kind: queueItemNamespace, key: subjectKey(subject)})
+		}
+
+		_ = c.clusterRoleBindingStore.Update(clusterRoleBindingSubjectRecord(clusterRoleBinding))
+	}
+
+	remove := func(obj interface{}) {
+		clusterRoleBinding, ok := obj.(*rbacv1.ClusterRoleBinding)
+		if !ok {
+			return
+		}
+
+		for _, subject := range clusterRoleBinding.Subjects {
+			c.queue.Add(queueItem{kind: queueItemNamespace, key: subjectKey(subject)})
+		}
+
+		_ = c.clusterRoleBindingStore.Delete(clusterRoleBindingSubjectRecord(clusterRoleBinding))
+	}
+
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(oldObj, newObj interface{}) { update(newObj) },
+		DeleteFunc: remove,
+	}
+}
+
+func (c *authCache) accountEventHandler() toolscache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		account, ok := obj.(*configv1alpha1.Account)
+		if !ok {
+			return
+		}
+
+		for _, subject := range account.Spec.Subjects {
+			c.queue.Add(queueItem{kind: queueItemAccount, key: subjectKey(subject)})
+		}
+	}
+
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) { enqueue(newObj) },
+		DeleteFunc: enqueue,
+	}
+}
+
+func (c *authCache) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *authCache) processNextItem() bool {
+	obj, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(obj)
+
+	atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+
+	item := obj.(queueItem)
+	if err := c.sync(item); err != nil {
+		c.log.Error(err, "failed to sync auth cache", "kind", item.kind, "key", item.key)
+		c.queue.AddRateLimited(obj)
+		return true
+	}
+
+	c.queue.Forget(obj)
+	return true
+}
+
+// sync resolves item's subject against every supported verb and caches each
+// verb's answer separately (see cacheKey) - a RoleBinding's PolicyRules can
+// grant different namespaces for different verbs, so a single verb-agnostic
+// list per subject would have to pick one verb's answer and serve it back
+// for all the others.
+func (c *authCache) sync(item queueItem) error {
+	ctx := context.Background()
+
+	switch item.kind {
+	case queueItemNamespace:
+		for verb := range supportedVerbs {
+			grants, err := c.accessor.RetrieveAllowedNamespacesWithReason(ctx, item.key, verb)
+			if err != nil {
+				return err
+			}
+
+			namespaces := make([]string, 0, len(grants))
+			for _, grant := range grants {
+				namespaces = append(namespaces, grant.Namespace)
+			}
+
+			c.allowedNamespaceStore.Set(cacheKey(item.key, verb), namespaces)
+			c.allowedNamespaceGrantStore.Set(cacheKey(item.key, verb), grants)
+		}
+	case queueItemAccount:
+		for verb := range supportedVerbs {
+			grants, err := c.accessor.RetrieveAllowedAccountsWithReason(ctx, item.key, verb)
+			if err != nil {
+				return err
+			}
+
+			accounts := make([]string, 0, len(grants))
+			for _, grant := range grants {
+				accounts = append(accounts, grant.Account)
+			}
+
+			c.allowedAccountStore.Set(cacheKey(item.key, verb), accounts)
+			c.allowedAccountGrantStore.Set(cacheKey(item.key, verb), grants)
+		}
+	}
+
+	return nil
+}
+
+func (c *authCache) GetNamespacesForUser(u user.Info, verb string) ([]string, error) {
+	if err := validateVerb(verb); err != nil {
+		return nil, err
+	}
+
+	namespaces, err := c.lookup(u, verb, c.allowedNamespaceStore, func(ctx context.Context, subject string) ([]string, error) {
+		return c.accessor.RetrieveAllowedNamespaces(ctx, subject, verb)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.auditNamespaceGrants(u, verb, namespaces)
+
+	return namespaces, nil
+}
+
+// auditNamespaceGrants emits one AuditEvent for each grant, held by the user
+// or by any of its groups, whose namespace is in namespaces - so a namespace
+// granted through several subjects or RoleBindings yields several events. It
+// uses the provenance sync() captured in allowedNamespaceGrantStore rather
+// than re-evaluating RBAC synchronously here - this runs on every
+// authorization decision, so it can't afford sync()'s List+Get cost. It
+// falls back to a live lookup only when the cache hasn't reconciled this
+// subject/verb yet, the same as lookup() does for the namespace list itself.
+// It is skipped entirely when no AuditSink is installed, so auditing costs
+// nothing when it isn't wanted.
+func (c *authCache) auditNamespaceGrants(u user.Info, verb string, namespaces []string) {
+	if c.auditSink == nil || len(namespaces) == 0 {
+		return
+	}
+
+	allowed := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		allowed[namespace] = true
+	}
+
+	emit := func(subjectKind, subjectName, key string) {
+		grants, ok := c.allowedNamespaceGrantStore.Get(cacheKey(key, verb))
+		if !ok {
+			var err error
+			grants, err = c.accessor.RetrieveAllowedNamespacesWithReason(context.Background(), key, verb)
+			if err != nil {
+				c.log.Error(err, "failed to resolve audit reason", "subject", key)
+				return
+			}
+		}
+
+		for _, grant := range grants {
+			if !allowed[grant.Namespace] {
+				continue
+			}
+
+			c.auditSink.Audit(AuditEvent{
+				SubjectKind: subjectKind,
+				SubjectName: subjectName,
+				Verb:        verb,
+				Resource:    "namespaces",
+				Namespace:   grant.Namespace,
+				RoleRef:     grant.RoleRef,
+				RuleIndex:   grant.RuleIndex,
+				Rule:        grant.Rule,
+			})
+		}
+	}
+
+	emit("User", u.GetName(), subjectKey(rbacv1.Subject{Kind: "User", Name: u.GetName()}))
+	for _, group := range u.GetGroups() {
+		emit("Group", group, subjectKey(rbacv1.Subject{Kind: "Group", Name: group}))
+	}
+}
+
+// SetAuditSink implements AuthCache.
+func (c *authCache) SetAuditSink(sink AuditSink) {
+	c.auditSink = sink
+}
+
+func (c *authCache) GetAccountsForUser(u user.Info, verb string) ([]string, error) {
+	if err := validateVerb(verb); err != nil {
+		return nil, err
+	}
+
+	accounts, err := c.lookup(u, verb, c.allowedAccountStore, func(ctx context.Context, subject string) ([]string, error) {
+		return c.accessor.RetrieveAllowedAccounts(ctx, subject, verb)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.auditAccountGrants(u, verb, accounts)
+
+	return accounts, nil
+}
+
+// auditAccountGrants emits one AuditEvent for each grant, held by the user or
+// by any of its groups, whose account is in accounts, reading
+// provenance from allowedAccountGrantStore the same way auditNamespaceGrants
+// does, instead of re-evaluating RBAC synchronously on every call.
+func (c *authCache) auditAccountGrants(u user.Info, verb string, accounts []string) {
+	if c.auditSink == nil || len(accounts) == 0 {
+		return
+	}
+
+	allowed := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		allowed[account] = true
+	}
+
+	emit := func(subjectKind, subjectName, key string) {
+		grants, ok := c.allowedAccountGrantStore.Get(cacheKey(key, verb))
+		if !ok {
+			var err error
+			grants, err = c.accessor.RetrieveAllowedAccountsWithReason(context.Background(), key, verb)
+			if err != nil {
+				c.log.Error(err, "failed to resolve audit reason", "subject", key)
+				return
+			}
+		}
+
+		for _, grant := range grants {
+			if !allowed[grant.Account] {
+				continue
+			}
+
+			c.auditSink.Audit(AuditEvent{
+				SubjectKind: subjectKind,
+				SubjectName: subjectName,
+				Verb:        verb,
+				Resource:    "accounts",
+				Account:     grant.Account,
+			})
+		}
+	}
+
+	emit("User", u.GetName(), subjectKey(rbacv1.Subject{Kind: "User", Name: u.GetName()}))
+	for _, group := range u.GetGroups() {
+		emit("Group", group, subjectKey(rbacv1.Subject{Kind: "Group", Name: group}))
+	}
+}
+
+// GetSubjectsForNamespace implements AuthCache.
+func (c *authCache) GetSubjectsForNamespace(namespace, verb string) ([]string, error) {
+	if err := validateVerb(verb); err != nil {
+		return nil, err
+	}
+
+	namespaced, err := c.subjectIndexer.ByIndex(subjectIndexByNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	result := []string{}
+	add := func(record *subjectRecord) error {
+		ruleIndex, _, err := matchingRule(context.Background(), c.client, namespace, record.roleRef, verb)
+		if err != nil {
+			return err
+		}
+		if ruleIndex == "" {
+			return nil
+		}
+
+		for _, subject := range record.subjects {
+			if !seen[subject] {
+				seen[subject] = true
+				result = append(result, subject)
+			}
+		}
+
+		return nil
+	}
+
+	for _, obj := range namespaced {
+		if err := add(obj.(*subjectRecord)); err != nil {
+			return nil, err
+		}
+	}
+	for _, obj := range c.clusterRoleBindingStore.List() {
+		if err := add(obj.(*subjectRecord)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// lookup is a straight index lookup on store, unioned across u's groups.
+// Entries are keyed by (subject, verb) via cacheKey, not subject alone, so a
+// cache hit for one verb is never served back as the answer for a different
+// one. When a subject has no entry in store - most likely because the event
+// that would have populated it hasn't been reconciled yet - it falls back to
+// asking the accessor directly, so the result is always a superset of what
+// asking the accessor for every subject up front would have returned.
+func (c *authCache) lookup(u user.Info, verb string, store *cacheStore, fallback func(ctx context.Context, subject string) ([]string, error)) ([]string, error) {
+	seen := map[string]bool{}
+	result := []string{}
+
+	add := func(key string) error {
+		values, ok := store.Get(cacheKey(key, verb))
+		if !ok {
+			var err error
+			values, err = fallback(context.Background(), key)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				result = append(result, v)
+			}
+		}
+
+		return nil
+	}
+
+	if err := add(subjectKey(rbacv1.Subject{Kind: "User", Name: u.GetName()})); err != nil {
+		return nil, err
+	}
+	for _, group := range u.GetGroups() {
+		if err := add(subjectKey(rbacv1.Subject{Kind: "Group", Name: group})); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func validateVerb(verb string) error {
+	if !supportedVerbs[verb] {
+		return fmt.Errorf("unsupported verb %q", verb)
+	}
+
+	return nil
+}
+
+func subjectKey(subject rbacv1.Subject) string {
+	return strings.ToLower(subject.Kind) + ":" + subject.Name
+}
+
+// cacheKey combines a subject and verb into the key allowedNamespaceStore and
+// allowedAccountStore use, so the namespaces/accounts cached for one verb are
+// never looked up - and returned - for a different one.
+func cacheKey(subject, verb string) string {
+	return subject + "|" + verb
+}
+
+// cacheStore is a minimal concurrency-safe key/value store keyed by
+// "subject|verb" (see cacheKey), used to hold the resolved namespaces/
+// accounts for each subject/verb pair.
+type cacheStore struct {
+	mu    sync.RWMutex
+	items map[string][]string
+}
+
+func newCacheStore() *cacheStore {
+	return &cacheStore{items: map[string][]string{}}
+}
+
+func (s *cacheStore) Get(key string) ([]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.items[key]
+	return v, ok
+}
+
+func (s *cacheStore) Set(key string, values []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = values
+}
+
+func (s *cacheStore) List() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.items))
+	for k := range s.items {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// namespaceGrantStore is a minimal concurrency-safe key/value store keyed by
+// "subject|verb" (see cacheKey), caching the NamespaceGrant provenance
+// sync() resolved for allowedNamespaceStore's entries.
+type namespaceGrantStore struct {
+	mu    sync.RWMutex
+	items map[string][]NamespaceGrant
+}
+
+func newNamespaceGrantStore() *namespaceGrantStore {
+	return &namespaceGrantStore{items: map[string][]NamespaceGrant{}}
+}
+
+func (s *namespaceGrantStore) Get(key string) ([]NamespaceGrant, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.items[key]
+	return v, ok
+}
+
+func (s *namespaceGrantStore) Set(key string, grants []NamespaceGrant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = grants
+}
+
+// accountGrantStore mirrors namespaceGrantStore for AccountGrant provenance.
+type accountGrantStore struct {
+	mu    sync.RWMutex
+	items map[string][]AccountGrant
+}
+
+func newAccountGrantStore() *accountGrantStore {
+	return &accountGrantStore{items: map[string][]AccountGrant{}}
+}
+
+func (s *accountGrantStore) Get(key string) ([]AccountGrant, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.items[key]
+	return v, ok
+}
+
+func (s *accountGrantStore) Set(key string, grants []AccountGrant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[key] = grants
+}
+
+// defaultAccessor is the accessor used outside of tests. It resolves access
+// by evaluating the subject against the RoleBindings and Accounts currently
+// in the cluster.
+type defaultAccessor struct {
+	client client.Client
+}
+
+func newDefaultAccessor(c client.Client) accessor {
+	return &defaultAccessor{client: c}
+}
+
+func (a *defaultAccessor) RetrieveAllowedNamespaces(ctx context.Context, subject, verb string) ([]string, error) {
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := a.client.List(ctx, roleBindingList); err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{}
+	for _, roleBinding := range roleBindingList.Items {
+		if !subjectListMatches(roleBinding.Subjects, subject) {
+			continue
+		}
+
+		ruleIndex, _, err := matchingRule(ctx, a.client, roleBinding.Namespace, roleBinding.RoleRef, verb)
+		if err != nil {
+			return nil, err
+		}
+		if ruleIndex == "" {
+			continue
+		}
+
+		namespaces = append(namespaces, roleBinding.Namespace)
+	}
+
+	return namespaces, nil
+}
+
+func (a *defaultAccessor) RetrieveAllowedAccounts(ctx context.Context, subject, verb string) ([]string, error) {
+	accountList := &configv1alpha1.AccountList{}
+	if err := a.client.List(ctx, accountList); err != nil {
+		return nil, err
+	}
+
+	accounts := []string{}
+	for _, account := range accountList.Items {
+		if subjectListMatches(account.Spec.Subjects, subject) {
+			accounts = append(accounts, account.Name)
+		}
+	}
+
+	return accounts, nil
+}
+
+// RetrieveAllowedNamespacesWithReason resolves the same grants as
+// RetrieveAllowedNamespaces, plus the RoleRef and PolicyRule that matched
+// verb in each one.
+func (a *defaultAccessor) RetrieveAllowedNamespacesWithReason(ctx context.Context, subject, verb string) ([]NamespaceGrant, error) {
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := a.client.List(ctx, roleBindingList); err != nil {
+		return nil, err
+	}
+
+	grants := []NamespaceGrant{}
+	for _, roleBinding := range roleBindingList.Items {
+		if !subjectListMatches(roleBinding.Subjects, subject) {
+			continue
+		}
+
+		ruleIndex, rule, err := matchingRule(ctx, a.client, roleBinding.Namespace, roleBinding.RoleRef, verb)
+		if err != nil {
+			return nil, err
+		}
+		if ruleIndex == "" {
+			continue
+		}
+
+		grants = append(grants, NamespaceGrant{
+			Namespace: roleBinding.Namespace,
+			RoleRef:   roleBinding.RoleRef.Name,
+			RuleIndex: ruleIndex,
+			Rule:      rule,
+		})
+	}
+
+	return grants, nil
+}
+
+// RetrieveAllowedAccountsWithReason resolves the same grants as
+// RetrieveAllowedAccounts. Accounts are granted directly through
+// Account.Spec.Subjects rather than through a RoleBinding, so - unlike
+// RetrieveAllowedNamespacesWithReason - there is no RoleRef or PolicyRule to
+// resolve; the Account itself is the provenance.
+func (a *defaultAccessor) RetrieveAllowedAccountsWithReason(ctx context.Context, subject, verb string) ([]AccountGrant, error) {
+	accountList := &configv1alpha1.AccountList{}
+	if err := a.client.List(ctx, accountList); err != nil {
+		return nil, err
+	}
+
+	grants := []AccountGrant{}
+	for _, account := range accountList.Items {
+		if subjectListMatches(account.Spec.Subjects, subject) {
+			grants = append(grants, AccountGrant{Account: account.Name})
+		}
+	}
+
+	return grants, nil
+}
+
+// matchingRule resolves roleRef to its (Cluster)Role and returns the index
+// and contents of the first PolicyRule granting verb on the "namespaces"
+// resource - the only resource this cache authorizes access to.
+func matchingRule(ctx context.Context, c client.Client, namespace string, roleRef rbacv1.RoleRef, verb string) (string, rbacv1.PolicyRule, error) {
+	var rules []rbacv1.PolicyRule
+
+	switch roleRef.Kind {
+	case "ClusterRole":
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := c.Get(ctx, client.ObjectKey{Name: roleRef.Name}, clusterRole); err != nil {
+			return "", rbacv1.PolicyRule{}, err
+		}
+		rules = clusterRole.Rules
+	case "Role":
+		role := &rbacv1.Role{}
+		if err := c.Get(ctx, client.ObjectKey{Name: roleRef.Name, Namespace: namespace}, role); err != nil {
+			return "", rbacv1.PolicyRule{}, err
+		}
+		rules = role.Rules
+	}
+
+	for i, rule := range rules {
+		if ruleMatchesVerb(rule, verb) && ruleMatchesResource(rule, "namespaces") {
+			return strconv.Itoa(i), rule, nil
+		}
+	}
+
+	return "", rbacv1.PolicyRule{}, nil
+}
+
+func ruleMatchesVerb(rule rbacv1.PolicyRule, verb string) bool {
+	if verb == "" {
+		return true
+	}
+
+	for _, v := range rule.Verbs {
+		if v == verb || v == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ruleMatchesResource(rule rbacv1.PolicyRule, resource string) bool {
+	for _, r := range rule.Resources {
+		if r == resource || r == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func subjectListMatches(subjects []rbacv1.Subject, key string) bool {
+	for _, subject := range subjects {
+		if subjectKey(subject) == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,8 +7,11 @@ import (
 	"time"
 
 	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+	kioskauth "github.com/kiosk-sh/kiosk/pkg/auth"
+	"github.com/kiosk-sh/kiosk/pkg/constants"
 	"github.com/kiosk-sh/kiosk/pkg/util"
 	testingutil "github.com/kiosk-sh/kiosk/pkg/util/testing"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -186,7 +189,7 @@ func TestCache(t *testing.T) {
 
 	// Wait for cache
 	err = wait.Poll(time.Millisecond*10, time.Second*5, func() (bool, error) {
-		_, ok := authcache.allowedNamespaceStore.Get("user:foo")
+		_, ok := authcache.allowedNamespaceStore.Get(cacheKey("user:foo", "get"))
 		return ok, nil
 	})
 	if err != nil {
@@ -211,7 +214,7 @@ func TestCache(t *testing.T) {
 
 	// Wait for cache
 	err = wait.Poll(time.Millisecond*10, time.Second*5, func() (bool, error) {
-		_, ok := authcache.allowedAccountStore.Get("group:bar")
+		_, ok := authcache.allowedAccountStore.Get(cacheKey("group:bar", "get"))
 		return ok, nil
 	})
 	if err != nil {
@@ -253,17 +256,190 @@ func TestCache(t *testing.T) {
 	}
 }
 
+// TestCacheIsVerbAware guards against a warm cache serving the namespaces
+// granted for one verb back as the answer for a different verb. The
+// fallback path taken on a cache miss was already verb-correct; the bug was
+// a cache hit returning whatever sync() happened to store first regardless
+// of the verb actually requested.
+func TestCacheIsVerbAware(t *testing.T) {
+	scheme := testingutil.NewScheme()
+	client := testingutil.NewFakeClient(scheme)
+	informerCache := testingutil.NewFakeCache(scheme)
+
+	cache, err := NewAuthCache(client, informerCache, zap.New(func(o *zap.Options) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authcache := cache.(*authCache)
+	fakeAccessor := &fakeAccessor{
+		allowedNamespacesByVerb: map[string]map[string][]string{
+			"user:foo": {
+				"get":    {"readable-namespace"},
+				"delete": {},
+			},
+		},
+	}
+	authcache.accessor = fakeAccessor
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	go authcache.Run(stopChan)
+
+	authcache.roleBindingInformer.(*testingutil.FakeInformer).Add(&rbacv1.RoleBinding{
+		Subjects: []rbacv1.Subject{{Kind: "User", Name: "foo"}},
+	})
+
+	if err := wait.Poll(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		_, ok := authcache.allowedNamespaceStore.Get(cacheKey("user:foo", "get"))
+		return ok, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &user.DefaultInfo{Name: "foo"}
+
+	namespaces, err := authcache.GetNamespacesForUser(u, "get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !util.StringsEqual(namespaces, []string{"readable-namespace"}) {
+		t.Fatalf("expected [readable-namespace] for verb get, got %#+v", namespaces)
+	}
+
+	namespaces, err = authcache.GetNamespacesForUser(u, "delete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(namespaces) != 0 {
+		t.Fatalf("expected no namespaces for verb delete on a warm cache, got %#+v", namespaces)
+	}
+}
+
+// TestAuthorizerChain exercises pkg/auth's AccountScopedAuthorizer,
+// RBACAuthorizer and WebhookAuthorizer independently through a chain built
+// with kioskauth.NewAuthorizerChain, the way AuthorizationOptions.New
+// composes them in production - one namespace per authorizer, so only that
+// authorizer's opinion can explain the resulting decision.
+func TestAuthorizerChain(t *testing.T) {
+	scheme := testingutil.NewScheme()
+	fakeClient := testingutil.NewFakeClient(scheme)
+	informerCache := testingutil.NewFakeCache(scheme)
+
+	cache, err := NewAuthCache(fakeClient, informerCache, zap.New(func(o *zap.Options) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authcache := cache.(*authCache)
+	fakeAccessor := &fakeAccessor{allowedNamespaces: map[string][]string{}}
+	authcache.accessor = fakeAccessor
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	go authcache.Run(stopChan)
+
+	// rbac-user is granted rbac-namespace only through a RoleBinding, cached
+	// by AuthCache.
+	fakeAccessor.lock.Lock()
+	fakeAccessor.allowedNamespaces["user:rbac-user"] = []string{"rbac-namespace"}
+	fakeAccessor.lock.Unlock()
+
+	authcache.roleBindingInformer.(*testingutil.FakeInformer).Add(&rbacv1.RoleBinding{
+		Subjects: []rbacv1.Subject{{Kind: "User", Name: "rbac-user"}},
+	})
+
+	if err := wait.Poll(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		_, ok := authcache.allowedNamespaceStore.Get(cacheKey("user:rbac-user", "get"))
+		return ok, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// account-user is granted account-namespace only by being listed directly
+	// in the Account that owns it, with no RoleBinding at all.
+	if err := fakeClient.Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "account-namespace",
+			Labels: map[string]string{constants.AccountLabel: "test-account"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Create(context.TODO(), &configv1alpha1.Account{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-account"},
+		Spec: configv1alpha1.AccountSpec{
+			Subjects: []rbacv1.Subject{{Kind: "User", Name: "account-user"}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// webhook-user is granted access to any namespace solely because the
+	// (fake) webhook backend says so.
+	chain := kioskauth.NewAuthorizerChain(
+		&kioskauth.AccountScopedAuthorizer{Client: fakeClient},
+		&kioskauth.RBACAuthorizer{Cache: authcache, StopCh: stopChan},
+		&kioskauth.WebhookAuthorizer{Client: &fakeSubjectAccessReviewCreator{allow: map[string]bool{"webhook-user": true}}},
+	)
+
+	tests := []struct {
+		name      string
+		user      string
+		namespace string
+		want      kioskauth.Decision
+	}{
+		{"rbac authorizer grants its namespace", "rbac-user", "rbac-namespace", kioskauth.DecisionAllow},
+		{"account-scoped authorizer grants its namespace with no RoleBinding", "account-user", "account-namespace", kioskauth.DecisionAllow},
+		{"webhook authorizer grants regardless of namespace", "webhook-user", "unmanaged-namespace", kioskauth.DecisionAllow},
+		{"unknown subject gets no opinion from any authorizer", "nobody", "rbac-namespace", kioskauth.DecisionNoOpinion},
+	}
+
+	for _, test := range tests {
+		decision, _, err := chain.Authorize(context.TODO(), kioskauth.Attributes{
+			User:      &user.DefaultInfo{Name: test.user},
+			Verb:      "get",
+			Namespace: test.namespace,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if decision != test.want {
+			t.Fatalf("%s: expected decision %v, got %v", test.name, test.want, decision)
+		}
+	}
+}
+
+type fakeSubjectAccessReviewCreator struct {
+	allow map[string]bool
+}
+
+func (f *fakeSubjectAccessReviewCreator) Create(ctx context.Context, sar *authorizationv1.SubjectAccessReview, opts metav1.CreateOptions) (*authorizationv1.SubjectAccessReview, error) {
+	result := sar.DeepCopy()
+	result.Status.Allowed = f.allow[sar.Spec.User]
+	return result, nil
+}
+
 type fakeAccessor struct {
 	lock sync.Mutex
 
 	allowedNamespaces map[string][]string
 	allowedAccounts   map[string][]string
+
+	// allowedNamespacesByVerb, when a subject has an entry here, overrides
+	// allowedNamespaces for that subject and answers per verb instead of the
+	// same list for every verb - see TestCacheIsVerbAware.
+	allowedNamespacesByVerb map[string]map[string][]string
 }
 
 func (f *fakeAccessor) RetrieveAllowedNamespaces(ctx context.Context, subject, verb string) ([]string, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
+	if byVerb, ok := f.allowedNamespacesByVerb[subject]; ok {
+		return byVerb[verb], nil
+	}
+
 	if f.allowedNamespaces == nil {
 		return nil, nil
 	}
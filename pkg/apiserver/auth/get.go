@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetNamespaces returns the namespaces named in namespaces, or every
+// namespace in the cluster if namespaces is ["*"]. Names that don't exist
+// are silently skipped, mirroring the behavior of GetAccounts.
+func GetNamespaces(ctx context.Context, c client.Client, namespaces []string) ([]corev1.Namespace, error) {
+	if len(namespaces) == 1 && namespaces[0] == "*" {
+		namespaceList := &corev1.NamespaceList{}
+		if err := c.List(ctx, namespaceList); err != nil {
+			return nil, err
+		}
+
+		return namespaceList.Items, nil
+	}
+
+	out := []corev1.Namespace{}
+	for _, name := range namespaces {
+		namespace := &corev1.Namespace{}
+		err := c.Get(ctx, client.ObjectKey{Name: name}, namespace)
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, *namespace)
+	}
+
+	return out, nil
+}
+
+// GetAccounts returns the accounts named in accounts, or every account in
+// the cluster if accounts is ["*"]. Names that don't exist are silently
+// skipped, mirroring the behavior of GetNamespaces.
+func GetAccounts(ctx context.Context, c client.Client, accounts []string) ([]configv1alpha1.Account, error) {
+	if len(accounts) == 1 && accounts[0] == "*" {
+		accountList := &configv1alpha1.AccountList{}
+		if err := c.List(ctx, accountList); err != nil {
+			return nil, err
+		}
+
+		return accountList.Items, nil
+	}
+
+	out := []configv1alpha1.Account{}
+	for _, name := range accounts {
+		account := &configv1alpha1.Account{}
+		err := c.Get(ctx, client.ObjectKey{Name: name}, account)
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		out = append(out, *account)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,43 @@
+// Package util holds small helpers shared by the apiserver registries and
+// the admission plugins that sit in front of them.
+package util
+
+import (
+	"context"
+	"fmt"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+	"github.com/kiosk-sh/kiosk/pkg/constants"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetClusterRoleFor returns the name of the ClusterRole that AccountReconciler
+// binds into every namespace owned by account, granting its subjects access.
+func GetClusterRoleFor(account *configv1alpha1.Account) string {
+	return fmt.Sprintf("kiosk-account-%s", account.Name)
+}
+
+// AccountForNamespace returns the Account that owns namespace, or nil if the
+// namespace isn't owned by one. It is the inverse of the IndexByAccount
+// lookup AccountReconciler performs to go from an Account to its namespaces.
+func AccountForNamespace(ctx context.Context, c client.Client, namespace string) (*configv1alpha1.Account, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, err
+	}
+
+	accountName, ok := ns.Labels[constants.AccountLabel]
+	if !ok {
+		return nil, nil
+	}
+
+	account := &configv1alpha1.Account{}
+	if err := c.Get(ctx, types.NamespacedName{Name: accountName}, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
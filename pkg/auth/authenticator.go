@@ -0,0 +1,198 @@
+// Package auth composes authentication and authorization into the
+// independently configurable, pluggable subsystems kiosk's API server and
+// admission webhooks use to decide who is making a request and what they are
+// allowed to do. The two concerns are deliberately kept separate: an
+// Authenticator only ever answers "who is this", an Authorizer only ever
+// answers "are they allowed to do this", and each is a chain of independently
+// enableable implementations rather than a single monolith.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+)
+
+// Authenticator authenticates an incoming request, returning the user it
+// resolved to, whether it recognized the request's credential at all, and an
+// error if something went wrong while trying to check it. A (nil, false, nil)
+// result means "I don't recognize this credential, ask the next
+// Authenticator"; a non-nil error means the check itself failed. A chain
+// built by NewAuthenticatorChain still asks the next Authenticator after an
+// error, and returns the collected errors only if none recognizes the
+// credential.
+type Authenticator interface {
+	AuthenticateRequest(req *http.Request) (user.Info, bool, error)
+}
+
+// authenticatorChain tries each Authenticator in order and returns the first
+// one that recognizes the request, mirroring
+// k8s.io/apiserver/pkg/authentication/request/union.
+type authenticatorChain []Authenticator
+
+// NewAuthenticatorChain composes authenticators into a single Authenticator
+// that tries each in the given order and returns the first one that
+// recognizes the request's credential.
+func NewAuthenticatorChain(authenticators ...Authenticator) Authenticator {
+	return authenticatorChain(authenticators)
+}
+
+// AuthenticateRequest implements Authenticator.
+func (chain authenticatorChain) AuthenticateRequest(req *http.Request) (user.Info, bool, error) {
+	var errs []error
+	for _, authenticator := range chain {
+		u, ok, err := authenticator.AuthenticateRequest(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			return u, true, nil
+		}
+	}
+
+	return nil, false, utilerrors.NewAggregate(errs)
+}
+
+// TokenReviewAuthenticator authenticates bearer tokens by delegating to the
+// Kubernetes API server's TokenReview endpoint, the same mechanism
+// kube-apiserver's webhook token authenticator uses.
+type TokenReviewAuthenticator struct {
+	Client authenticationv1client.AuthenticationV1Interface
+}
+
+// AuthenticateRequest implements Authenticator.
+func (a *TokenReviewAuthenticator) AuthenticateRequest(req *http.Request) (user.Info, bool, error) {
+	token := bearerToken(req)
+	if token == "" {
+		return nil, false, nil
+	}
+
+	review, err := a.Client.TokenReviews().Create(req.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	if !review.Status.Authenticated {
+		return nil, false, nil
+	}
+
+	return &user.DefaultInfo{
+		Name:   review.Status.User.Username,
+		UID:    review.Status.User.UID,
+		Groups: review.Status.User.Groups,
+	}, true, nil
+}
+
+// OIDCAuthenticator authenticates bearer tokens as OIDC ID tokens, verifying
+// the signature and claims against the discovery document published at
+// IssuerURL - the same mechanism kube-apiserver's --oidc-* flags configure.
+type OIDCAuthenticator struct {
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+	groupsClaim   string
+}
+
+// newOIDCAuthenticator discovers the OIDC provider at issuerURL and builds an
+// OIDCAuthenticator that verifies tokens against it.
+func newOIDCAuthenticator(issuerURL, clientID, usernameClaim, groupsClaim string) (*OIDCAuthenticator, error) {
+	provider, err := oidc.NewProvider(context.Background(), issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering OIDC provider %q: %w", issuerURL, err)
+	}
+
+	return &OIDCAuthenticator{
+		verifier:      provider.Verifier(&oidc.Config{ClientID: clientID}),
+		usernameClaim: usernameClaim,
+		groupsClaim:   groupsClaim,
+	}, nil
+}
+
+// AuthenticateRequest implements Authenticator.
+func (a *OIDCAuthenticator) AuthenticateRequest(req *http.Request) (user.Info, bool, error) {
+	token := bearerToken(req)
+	if token == "" {
+		return nil, false, nil
+	}
+
+	idToken, err := a.verifier.Verify(req.Context(), token)
+	if err != nil {
+		// An unverifiable token isn't necessarily an error - it may simply
+		// belong to a different Authenticator further down the chain.
+		return nil, false, nil
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, false, err
+	}
+
+	username, _ := claims[a.usernameClaim].(string)
+	if username == "" {
+		return nil, false, fmt.Errorf("OIDC token is missing claim %q", a.usernameClaim)
+	}
+
+	var groups []string
+	if raw, ok := claims[a.groupsClaim].([]interface{}); ok {
+		for _, g := range raw {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+
+	return &user.DefaultInfo{Name: username, Groups: groups}, true, nil
+}
+
+// StaticAdminAuthenticator authenticates a single bootstrap administrator via
+// HTTP basic auth. It exists so a freshly installed kiosk has one account
+// that can always get in, before any RoleBinding, TokenReview backend, or
+// OIDC provider has been configured. It always grants system:masters, so the
+// credential it checks is as sensitive as any in the system.
+type StaticAdminAuthenticator struct {
+	Email    string
+	Password string
+}
+
+// AuthenticateRequest implements Authenticator.
+func (a *StaticAdminAuthenticator) AuthenticateRequest(req *http.Request) (user.Info, bool, error) {
+	if a.Email == "" || a.Password == "" {
+		return nil, false, nil
+	}
+
+	email, password, ok := req.BasicAuth()
+	if !ok || email != a.Email || !passwordsEqual(password, a.Password) {
+		return nil, false, nil
+	}
+
+	return &user.DefaultInfo{
+		Name:   a.Email,
+		Groups: []string{"system:masters"},
+	}, true, nil
+}
+
+// passwordsEqual compares got and want in constant time, so a timing
+// difference in how quickly AuthenticateRequest rejects a guess can't leak
+// how many leading bytes of the admin password it got right.
+func passwordsEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func bearerToken(req *http.Request) string {
+	header := req.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimPrefix(header, "Bearer ")
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apiserverauth "github.com/kiosk-sh/kiosk/pkg/apiserver/auth"
+	registryutil "github.com/kiosk-sh/kiosk/pkg/apiserver/registry/util"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Attributes describes the request an Authorizer decides on, mirroring the
+// subset of k8s.io/apiserver/pkg/authorization/authorizer.Attributes that
+// kiosk's checks need.
+type Attributes struct {
+	User      user.Info
+	Verb      string
+	Namespace string
+}
+
+// Decision is the outcome of an Authorizer's check.
+type Decision int
+
+const (
+	// DecisionNoOpinion means this Authorizer doesn't know how to decide on
+	// attrs, and a later Authorizer in the chain should get a chance to.
+	DecisionNoOpinion Decision = iota
+	// DecisionAllow means this Authorizer explicitly allows attrs.
+	DecisionAllow
+	// DecisionDeny means this Authorizer explicitly denies attrs, which (unlike
+	// DecisionNoOpinion) short-circuits the rest of the chain.
+	DecisionDeny
+)
+
+// Authorizer decides whether attrs is allowed. Returning DecisionNoOpinion
+// lets a later Authorizer in a chain make the call instead.
+type Authorizer interface {
+	Authorize(ctx context.Context, attrs Attributes) (Decision, string, error)
+}
+
+// authorizerChain asks each Authorizer in order and returns the first
+// decision that isn't DecisionNoOpinion, mirroring
+// k8s.io/apiserver/pkg/authorization/union.
+type authorizerChain []Authorizer
+
+// NewAuthorizerChain composes authorizers into a single Authorizer that asks
+// each in the given order and returns the first one's opinion.
+func NewAuthorizerChain(authorizers ...Authorizer) Authorizer {
+	return authorizerChain(authorizers)
+}
+
+// Authorize implements Authorizer.
+func (chain authorizerChain) Authorize(ctx context.Context, attrs Attributes) (Decision, string, error) {
+	var reasons []string
+	for _, authorizer := range chain {
+		decision, reason, err := authorizer.Authorize(ctx, attrs)
+		if err != nil {
+			return DecisionNoOpinion, "", err
+		}
+		if decision != DecisionNoOpinion {
+			return decision, reason, nil
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	return DecisionNoOpinion, fmt.Sprint(reasons), nil
+}
+
+// RBACAuthorizer allows attrs when the subject's cached namespaces - resolved
+// by apiserverauth.AuthCache from the RoleBindings in each namespace -
+// include attrs.Namespace. ClusterRoleBindings do not add namespaces to that
+// list.
+type RBACAuthorizer struct {
+	Cache apiserverauth.AuthCache
+
+	// StopCh is passed to Cache.WaitForCacheSync before the first lookup, so
+	// Authorize doesn't start serving decisions off a cache that's still
+	// replaying its initial list and can spuriously report no access yet.
+	// Closing it while a wait is in progress aborts that wait, the same as
+	// it would abort Cache.Run.
+	StopCh <-chan struct{}
+
+	syncOnce sync.Once
+}
+
+// Authorize implements Authorizer.
+func (a *RBACAuthorizer) Authorize(ctx context.Context, attrs Attributes) (Decision, string, error) {
+	if attrs.Namespace == "" {
+		return DecisionNoOpinion, "", nil
+	}
+
+	a.syncOnce.Do(func() { a.Cache.WaitForCacheSync(a.StopCh) })
+
+	namespaces, err := a.Cache.GetNamespacesForUser(attrs.User, attrs.Verb)
+	if err != nil {
+		return DecisionNoOpinion, "", err
+	}
+
+	for _, namespace := range namespaces {
+		if namespace == attrs.Namespace {
+			return DecisionAllow, "allowed by RoleBinding", nil
+		}
+	}
+
+	return DecisionNoOpinion, "", nil
+}
+
+// AccountScopedAuthorizer allows attrs when the subject is listed directly in
+// the Account that owns attrs.Namespace, short-circuiting the RBAC lookup for
+// the common case of an account owner reaching their own namespace before any
+// RoleBinding has been created for it.
+type AccountScopedAuthorizer struct {
+	Client client.Client
+}
+
+// Authorize implements Authorizer.
+func (a *AccountScopedAuthorizer) Authorize(ctx context.Context, attrs Attributes) (Decision, string, error) {
+	if attrs.Namespace == "" {
+		return DecisionNoOpinion, "", nil
+	}
+
+	account, err := registryutil.AccountForNamespace(ctx, a.Client, attrs.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return DecisionNoOpinion, "", nil
+		}
+
+		return DecisionNoOpinion, "", err
+	}
+	if account == nil {
+		return DecisionNoOpinion, "", nil
+	}
+
+	for _, subject := range account.Spec.Subjects {
+		if subjectMatchesUser(subject, attrs.User) {
+			return DecisionAllow, fmt.Sprintf("subject listed in account %q", account.Name), nil
+		}
+	}
+
+	return DecisionNoOpinion, "", nil
+}
+
+func subjectMatchesUser(subject rbacv1.Subject, u user.Info) bool {
+	switch subject.Kind {
+	case "User":
+		return subject.Name == u.GetName()
+	case "Group":
+		for _, group := range u.GetGroups() {
+			if group == subject.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SubjectAccessReviewCreator is the subset of
+// k8s.io/client-go/kubernetes/typed/authorization/v1.SubjectAccessReviewInterface
+// that WebhookAuthorizer depends on, so it can be faked in tests without a
+// real API server to send SubjectAccessReviews to.
+type SubjectAccessReviewCreator interface {
+	Create(ctx context.Context, sar *authorizationv1.SubjectAccessReview, opts metav1.CreateOptions) (*authorizationv1.SubjectAccessReview, error)
+}
+
+// WebhookAuthorizer delegates the decision to an external SubjectAccessReview
+// backend, the same pattern kube-apiserver's --authorization-webhook-config-file
+// uses, for clusters that already centralize authorization decisions outside
+// kiosk.
+type WebhookAuthorizer struct {
+	Client SubjectAccessReviewCreator
+}
+
+// Authorize implements Authorizer.
+func (a *WebhookAuthorizer) Authorize(ctx context.Context, attrs Attributes) (Decision, string, error) {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   attrs.User.GetName(),
+			Groups: attrs.User.GetGroups(),
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: attrs.Namespace,
+				Verb:      attrs.Verb,
+				Resource:  "namespaces",
+			},
+		},
+	}
+
+	result, err := a.Client.Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return DecisionNoOpinion, "", err
+	}
+
+	switch {
+	case result.Status.Denied:
+		return DecisionDeny, "denied by webhook", nil
+	case result.Status.Allowed:
+		return DecisionAllow, "allowed by webhook", nil
+	default:
+		return DecisionNoOpinion, "", nil
+	}
+}
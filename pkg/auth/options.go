@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"fmt"
+
+	apiserverauth "github.com/kiosk-sh/kiosk/pkg/apiserver/auth"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AuthenticationOptions holds the flags that select and configure which
+// Authenticators NewAuthenticationOptions().New chains together.
+type AuthenticationOptions struct {
+	TokenReview bool
+
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCUsernameClaim string
+	OIDCGroupsClaim   string
+
+	AdminEmail    string
+	AdminPassword string
+}
+
+// NewAuthenticationOptions returns AuthenticationOptions with kiosk's
+// long-standing default: token review authentication on, OIDC and the
+// bootstrap admin off until explicitly configured.
+func NewAuthenticationOptions() *AuthenticationOptions {
+	return &AuthenticationOptions{TokenReview: true}
+}
+
+// AddFlags registers the authentication flags onto fs.
+func (o *AuthenticationOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.TokenReview, "authentication-token-review", o.TokenReview, "Authenticate bearer tokens via the Kubernetes TokenReview API")
+	fs.StringVar(&o.OIDCIssuerURL, "oidc-issuer-url", o.OIDCIssuerURL, "OIDC issuer URL to verify ID tokens against")
+	fs.StringVar(&o.OIDCClientID, "oidc-client-id", o.OIDCClientID, "OIDC client ID the ID token's audience must match")
+	fs.StringVar(&o.OIDCUsernameClaim, "oidc-username-claim", "email", "OIDC claim to use as the username")
+	fs.StringVar(&o.OIDCGroupsClaim, "oidc-groups-claim", "groups", "OIDC claim to use as the group list")
+	fs.StringVar(&o.AdminEmail, "admin-email", o.AdminEmail, "Bootstrap administrator email, authenticated via HTTP basic auth")
+	fs.StringVar(&o.AdminPassword, "admin-password", o.AdminPassword, "Bootstrap administrator password")
+}
+
+// Validate checks that the combination of flags is usable without contacting
+// anything external.
+func (o *AuthenticationOptions) Validate() error {
+	if o.OIDCIssuerURL != "" && o.OIDCClientID == "" {
+		return fmt.Errorf("--oidc-client-id is required when --oidc-issuer-url is set")
+	}
+	if (o.AdminEmail == "") != (o.AdminPassword == "") {
+		return fmt.Errorf("--admin-email and --admin-password must be set together")
+	}
+	if !o.TokenReview && o.OIDCIssuerURL == "" && o.AdminEmail == "" {
+		return fmt.Errorf("at least one authenticator must be enabled")
+	}
+
+	return nil
+}
+
+// New builds the Authenticator chain described by o.
+func (o *AuthenticationOptions) New(kubeClient kubernetes.Interface) (Authenticator, error) {
+	var authenticators []Authenticator
+
+	if o.TokenReview {
+		authenticators = append(authenticators, &TokenReviewAuthenticator{Client: kubeClient.AuthenticationV1()})
+	}
+	if o.OIDCIssuerURL != "" {
+		oidcAuthenticator, err := newOIDCAuthenticator(o.OIDCIssuerURL, o.OIDCClientID, o.OIDCUsernameClaim, o.OIDCGroupsClaim)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring OIDC authenticator: %w", err)
+		}
+		authenticators = append(authenticators, oidcAuthenticator)
+	}
+	if o.AdminEmail != "" {
+		authenticators = append(authenticators, &StaticAdminAuthenticator{Email: o.AdminEmail, Password: o.AdminPassword})
+	}
+
+	return NewAuthenticatorChain(authenticators...), nil
+}
+
+// AuthorizationOptions holds the flags that select and configure which
+// Authorizers NewAuthorizationOptions().New chains together.
+type AuthorizationOptions struct {
+	RBAC          bool
+	AccountScoped bool
+
+	WebhookKubeconfig string
+}
+
+// NewAuthorizationOptions returns AuthorizationOptions with kiosk's
+// long-standing default: RBAC and account-scoped authorization on, the
+// webhook authorizer off until a kubeconfig is provided for it.
+func NewAuthorizationOptions() *AuthorizationOptions {
+	return &AuthorizationOptions{RBAC: true, AccountScoped: true}
+}
+
+// AddFlags registers the authorization flags onto fs.
+func (o *AuthorizationOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.RBAC, "authorization-rbac", o.RBAC, "Authorize requests against namespace RoleBindings cached by AuthCache")
+	fs.BoolVar(&o.AccountScoped, "authorization-account-scoped", o.AccountScoped, "Authorize requests whose subject is listed directly in the Account that owns the namespace")
+	fs.StringVar(&o.WebhookKubeconfig, "authorization-webhook-kubeconfig", o.WebhookKubeconfig, "Kubeconfig for a SubjectAccessReview webhook authorizer")
+}
+
+// Validate checks that the combination of flags is usable without contacting
+// anything external.
+func (o *AuthorizationOptions) Validate() error {
+	if !o.RBAC && !o.AccountScoped && o.WebhookKubeconfig == "" {
+		return fmt.Errorf("at least one authorizer must be enabled")
+	}
+
+	return nil
+}
+
+// New builds the Authorizer chain described by o. cache backs RBACAuthorizer
+// and c backs AccountScopedAuthorizer; webhookClient backs WebhookAuthorizer
+// and may be nil if o.WebhookKubeconfig is empty. stopCh is forwarded to
+// RBACAuthorizer so it can block on cache.WaitForCacheSync before serving
+// its first decision; it should be the same stop channel passed to the
+// manager that runs cache.Run.
+//
+// Account-scoped authorization runs first: it is the cheapest check (a
+// Namespace and Account Get, no informer cache involved) and answers the
+// common case of an account owner reaching their own namespace before any
+// RoleBinding has been created for it.
+func (o *AuthorizationOptions) New(cache apiserverauth.AuthCache, c client.Client, webhookClient SubjectAccessReviewCreator, stopCh <-chan struct{}) (Authorizer, error) {
+	var authorizers []Authorizer
+
+	if o.AccountScoped {
+		authorizers = append(authorizers, &AccountScopedAuthorizer{Client: c})
+	}
+	if o.RBAC {
+		authorizers = append(authorizers, &RBACAuthorizer{Cache: cache, StopCh: stopCh})
+	}
+	if o.WebhookKubeconfig != "" {
+		authorizers = append(authorizers, &WebhookAuthorizer{Client: webhookClient})
+	}
+
+	return NewAuthorizerChain(authorizers...), nil
+}
@@ -0,0 +1,18 @@
+// Package constants holds shared constant values that are referenced across
+// multiple kiosk packages (controllers, admission plugins, apiserver), to
+// avoid magic strings drifting out of sync between them.
+package constants
+
+const (
+	// IndexByAccount is the name of the field indexer, registered on
+	// Namespace and RoleBinding objects, that maps an owning Account's name
+	// to the objects it owns. AccountReconciler uses it to list a given
+	// account's namespaces/rolebindings without listing every object in the
+	// cluster.
+	IndexByAccount = "index.kiosk.sh/account"
+
+	// AccountLabel is set by AccountReconciler on every namespace it owns,
+	// recording the name of the owning Account. It backs IndexByAccount and
+	// is also how callers resolve the Account for a given namespace.
+	AccountLabel = "account.kiosk.sh/name"
+)
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+	registryutil "github.com/kiosk-sh/kiosk/pkg/apiserver/registry/util"
+	"github.com/kiosk-sh/kiosk/pkg/constants"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// roleBindingName is the name AccountReconciler gives the RoleBinding it
+// maintains in every namespace owned by an Account.
+const roleBindingName = "kiosk-account-access"
+
+// AccountReconciler reconciles an Account. It keeps a RoleBinding in every
+// namespace the account owns up to date with the account's subjects, and
+// records the owned namespaces on the account's status.
+type AccountReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *AccountReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("account", req.Name)
+
+	account := &configv1alpha1.Account{}
+	if err := r.Get(ctx, types.NamespacedName{Name: req.Name}, account); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaceList, client.MatchingFields{constants.IndexByAccount: account.Name}); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	clusterRole := registryutil.GetClusterRoleFor(account)
+	namespaces := make([]configv1alpha1.AccountStatusNamespace, 0, len(namespaceList.Items))
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+
+		if err := r.reconcileNamespaceLabel(ctx, namespace, account.Name); err != nil {
+			log.Error(err, "label namespace", "namespace", namespace.Name)
+			return reconcile.Result{}, err
+		}
+
+		if err := r.reconcileRoleBinding(ctx, account, namespace.Name, clusterRole); err != nil {
+			log.Error(err, "reconcile role binding", "namespace", namespace.Name)
+			return reconcile.Result{}, err
+		}
+
+		namespaces = append(namespaces, configv1alpha1.AccountStatusNamespace{Name: namespace.Name})
+	}
+
+	account.Status.Namespaces = namespaces
+	if err := r.Status().Update(ctx, account); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileNamespaceLabel ensures namespace carries constants.AccountLabel
+// recording accountName as its owner. registryutil.AccountForNamespace - and
+// everything built on it, like the PodSecurity admission plugin and
+// AccountScopedAuthorizer - resolves the account that owns a namespace by
+// reading this label back, so it has to be set somewhere; this is the only
+// reconciler that knows the namespace/account relationship.
+func (r *AccountReconciler) reconcileNamespaceLabel(ctx context.Context, namespace *corev1.Namespace, accountName string) error {
+	if namespace.Labels[constants.AccountLabel] == accountName {
+		return nil
+	}
+
+	if namespace.Labels == nil {
+		namespace.Labels = map[string]string{}
+	}
+	namespace.Labels[constants.AccountLabel] = accountName
+
+	return r.Update(ctx, namespace)
+}
+
+// reconcileRoleBinding creates or updates the RoleBinding that grants
+// account's subjects access to namespace via clusterRole.
+func (r *AccountReconciler) reconcileRoleBinding(ctx context.Context, account *configv1alpha1.Account, namespace, clusterRole string) error {
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     clusterRole,
+	}
+
+	roleBinding := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, types.NamespacedName{Name: roleBindingName, Namespace: namespace}, roleBinding)
+	if apierrors.IsNotFound(err) {
+		roleBinding = &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      roleBindingName,
+				Namespace: namespace,
+			},
+			Subjects: account.Spec.Subjects,
+			RoleRef:  roleRef,
+		}
+
+		return r.Create(ctx, roleBinding)
+	} else if err != nil {
+		return err
+	}
+
+	roleBinding.Subjects = account.Spec.Subjects
+	roleBinding.RoleRef = roleRef
+	return r.Update(ctx, roleBinding)
+}
+
+// SetupWithManager registers this reconciler with mgr.
+func (r *AccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&configv1alpha1.Account{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Complete(r)
+}
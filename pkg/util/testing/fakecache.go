@@ -0,0 +1,46 @@
+package testing
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/kiosk-sh/kiosk/pkg/apiserver/auth"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FakeCache is a minimal stand-in for controller-runtime's cache.Cache that
+// hands out one FakeInformer per object type, the way the real informer
+// cache hands out one shared informer per GroupVersionKind.
+type FakeCache struct {
+	scheme *runtime.Scheme
+
+	mu        sync.Mutex
+	informers map[reflect.Type]*FakeInformer
+}
+
+// NewFakeCache returns an empty FakeCache for scheme.
+func NewFakeCache(scheme *runtime.Scheme) *FakeCache {
+	return &FakeCache{
+		scheme:    scheme,
+		informers: map[reflect.Type]*FakeInformer{},
+	}
+}
+
+// GetInformer returns the FakeInformer registered for obj's concrete type,
+// creating it on first use.
+func (f *FakeCache) GetInformer(ctx context.Context, obj client.Object) (auth.Informer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := reflect.TypeOf(obj)
+	informer, ok := f.informers[t]
+	if !ok {
+		informer = NewFakeInformer()
+		f.informers[t] = informer
+	}
+
+	return informer, nil
+}
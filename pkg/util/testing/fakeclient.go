@@ -0,0 +1,77 @@
+package testing
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// FakeClient is a controller-runtime fake client augmented with a manual
+// field-index store, so tests can seed the result of an indexed List call
+// (e.g. "namespaces owned by this account") without standing up a real
+// field indexer.
+type FakeClient struct {
+	client.Client
+
+	mu      sync.Mutex
+	indexes map[indexKey][]runtime.Object
+}
+
+type indexKey struct {
+	gvk       schema.GroupVersionKind
+	indexName string
+	value     string
+}
+
+// NewFakeClient returns a FakeClient backed by an empty in-memory object
+// tracker for scheme.
+func NewFakeClient(scheme *runtime.Scheme) *FakeClient {
+	return &FakeClient{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).Build(),
+		indexes: map[indexKey][]runtime.Object{},
+	}
+}
+
+// SetIndexValue seeds the objects a List call for the given field index
+// should return, mirroring the field indexers the manager registers on
+// controllers such as AccountReconciler (see constants.IndexByAccount).
+func (c *FakeClient) SetIndexValue(gvk schema.GroupVersionKind, indexName, value string, objs []runtime.Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.indexes[indexKey{gvk: gvk, indexName: indexName, value: value}] = objs
+}
+
+// List delegates to the underlying fake client, except when the caller asks
+// for a field index that was seeded via SetIndexValue, in which case the
+// seeded objects are returned directly.
+func (c *FakeClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	if listOpts.FieldSelector != nil {
+		gvks, _, err := c.Client.Scheme().ObjectKinds(list)
+		if err == nil && len(gvks) > 0 {
+			gvk := gvks[0]
+			gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+			for _, req := range listOpts.FieldSelector.Requirements() {
+				c.mu.Lock()
+				objs, ok := c.indexes[indexKey{gvk: gvk, indexName: req.Field, value: req.Value}]
+				c.mu.Unlock()
+
+				if ok {
+					return meta.SetList(list, objs)
+				}
+			}
+		}
+	}
+
+	return c.Client.List(ctx, list, opts...)
+}
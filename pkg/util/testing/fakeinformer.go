@@ -0,0 +1,73 @@
+package testing
+
+import (
+	"sync"
+
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// FakeInformer is a minimal in-memory stand-in for a client-go shared
+// informer that lets tests drive Add/Update/Delete events directly, instead
+// of running a real watch against a fake apiserver.
+type FakeInformer struct {
+	mu       sync.Mutex
+	handlers []toolscache.ResourceEventHandler
+	synced   bool
+}
+
+// NewFakeInformer returns a FakeInformer that reports HasSynced() == true.
+func NewFakeInformer() *FakeInformer {
+	return &FakeInformer{synced: true}
+}
+
+// AddEventHandler implements auth.Informer.
+func (f *FakeInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers = append(f.handlers, handler)
+}
+
+// HasSynced implements auth.Informer.
+func (f *FakeInformer) HasSynced() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.synced
+}
+
+// SetSynced lets a test simulate an informer whose initial list hasn't
+// completed yet.
+func (f *FakeInformer) SetSynced(synced bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.synced = synced
+}
+
+// Add fires an OnAdd event on every registered handler, as if obj had just
+// been observed by the informer's initial list or subsequent watch.
+func (f *FakeInformer) Add(obj interface{}) {
+	f.notify(func(h toolscache.ResourceEventHandler) { h.OnAdd(obj) })
+}
+
+// Update fires an OnUpdate event on every registered handler.
+func (f *FakeInformer) Update(oldObj, newObj interface{}) {
+	f.notify(func(h toolscache.ResourceEventHandler) { h.OnUpdate(oldObj, newObj) })
+}
+
+// Delete fires an OnDelete event on every registered handler.
+func (f *FakeInformer) Delete(obj interface{}) {
+	f.notify(func(h toolscache.ResourceEventHandler) { h.OnDelete(obj) })
+}
+
+func (f *FakeInformer) notify(fn func(toolscache.ResourceEventHandler)) {
+	f.mu.Lock()
+	handlers := make([]toolscache.ResourceEventHandler, len(f.handlers))
+	copy(handlers, f.handlers)
+	f.mu.Unlock()
+
+	for _, h := range handlers {
+		fn(h)
+	}
+}
@@ -0,0 +1,21 @@
+package testing
+
+import (
+	configv1alpha1 "github.com/kiosk-sh/kiosk/pkg/apis/config/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// NewScheme returns a runtime.Scheme with the core, rbac and kiosk config
+// types registered, for use by fake clients and caches in tests.
+func NewScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	_ = scheme.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+	_ = rbacv1.AddToScheme(s)
+	_ = configv1alpha1.AddToScheme(s)
+	return s
+}